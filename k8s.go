@@ -0,0 +1,198 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// PodInfo captures the downward-API fields surfaced alongside CNFStatus.
+type PodInfo struct {
+	PodIPs             []string          `json:"pod_ips"`
+	HostIP             string            `json:"host_ip"`
+	UID                string            `json:"uid"`
+	Labels             map[string]string `json:"labels"`
+	Annotations        map[string]string `json:"annotations"`
+	ServiceAccountName string            `json:"service_account_name"`
+	Resources          PodResources      `json:"resources"`
+}
+
+// PodResources mirrors the resource limits/requests exposed via the downward API.
+type PodResources struct {
+	CPURequest    string `json:"cpu_request"`
+	CPULimit      string `json:"cpu_limit"`
+	MemoryRequest string `json:"memory_request"`
+	MemoryLimit   string `json:"memory_limit"`
+}
+
+// podInfoFromEnv builds a PodInfo from the downward API env vars a Kubernetes
+// Deployment would inject. Fields default to empty when running outside Kubernetes.
+func podInfoFromEnv() PodInfo {
+	return PodInfo{
+		PodIPs:             splitNonEmpty(os.Getenv("KUBERNETES_POD_IPS"), ","),
+		HostIP:             os.Getenv("KUBERNETES_HOST_IP"),
+		UID:                os.Getenv("KUBERNETES_POD_UID"),
+		Labels:             parseKeyValueList(os.Getenv("KUBERNETES_POD_LABELS")),
+		Annotations:        parseKeyValueList(os.Getenv("KUBERNETES_POD_ANNOTATIONS")),
+		ServiceAccountName: os.Getenv("KUBERNETES_SERVICE_ACCOUNT"),
+		Resources: PodResources{
+			CPURequest:    os.Getenv("KUBERNETES_CPU_REQUEST"),
+			CPULimit:      os.Getenv("KUBERNETES_CPU_LIMIT"),
+			MemoryRequest: os.Getenv("KUBERNETES_MEMORY_REQUEST"),
+			MemoryLimit:   os.Getenv("KUBERNETES_MEMORY_LIMIT"),
+		},
+	}
+}
+
+func splitNonEmpty(s, sep string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(s, sep)
+}
+
+// parseKeyValueList parses the "key=value,key2=value2" format the downward API uses
+// when labels/annotations are flattened into a single env var.
+func parseKeyValueList(s string) map[string]string {
+	if s == "" {
+		return nil
+	}
+	out := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) == 2 {
+			out[kv[0]] = kv[1]
+		}
+	}
+	return out
+}
+
+// Peer describes a sibling pod discovered via the in-cluster peer watcher.
+type Peer struct {
+	ID     string `json:"id"`
+	PodIP  string `json:"pod_ip"`
+	Ready  bool   `json:"ready"`
+	CNFVer string `json:"cnf_version"`
+}
+
+// peerWatcher watches sibling pods sharing this CNF's label selector and keeps an
+// up-to-date peer list for /peers. It falls back to an empty, disabled state when
+// IN_CLUSTER is not "true" or in-cluster credentials/RBAC are unavailable.
+type peerWatcher struct {
+	mu      sync.RWMutex
+	enabled bool
+	peers   []Peer
+}
+
+var peers = &peerWatcher{}
+
+// startPeerWatcher begins watching sibling pods when IN_CLUSTER=true. Any failure to
+// build an in-cluster client or watch pods (e.g. missing RBAC) is logged and the watcher
+// stays disabled, so statusHandler and /peers keep working in env-only mode.
+func startPeerWatcher(ctx context.Context) {
+	if os.Getenv("IN_CLUSTER") != "true" {
+		return
+	}
+
+	config, err := rest.InClusterConfig()
+	if err != nil {
+		log.Printf("peer watcher disabled: failed to load in-cluster config: %v", err)
+		return
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		log.Printf("peer watcher disabled: failed to build clientset: %v", err)
+		return
+	}
+
+	namespace := os.Getenv("KUBERNETES_NAMESPACE")
+	if namespace == "" {
+		namespace = "default"
+	}
+	selector := os.Getenv("KUBERNETES_POD_LABEL_SELECTOR")
+
+	watcher, err := clientset.CoreV1().Pods(namespace).Watch(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		log.Printf("peer watcher disabled: failed to watch pods: %v", err)
+		return
+	}
+
+	peers.mu.Lock()
+	peers.enabled = true
+	peers.mu.Unlock()
+
+	go func() {
+		for event := range watcher.ResultChan() {
+			pod, ok := event.Object.(*corev1.Pod)
+			if !ok {
+				continue
+			}
+			if event.Type == watch.Deleted {
+				peers.remove(pod)
+				continue
+			}
+			peers.update(pod)
+		}
+	}()
+}
+
+// update refreshes this pod's entry in the peer list from the latest watch event.
+func (pw *peerWatcher) update(pod *corev1.Pod) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	peer := Peer{
+		ID:     string(pod.UID),
+		PodIP:  pod.Status.PodIP,
+		Ready:  isPodReady(pod),
+		CNFVer: pod.Labels["cnf-version"],
+	}
+
+	for i, existing := range pw.peers {
+		if existing.ID == peer.ID {
+			pw.peers[i] = peer
+			return
+		}
+	}
+	pw.peers = append(pw.peers, peer)
+}
+
+// remove drops a terminated/evicted pod from the peer list so /peers reflects current
+// topology rather than accumulating stale entries forever.
+func (pw *peerWatcher) remove(pod *corev1.Pod) {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+
+	id := string(pod.UID)
+	for i, existing := range pw.peers {
+		if existing.ID == id {
+			pw.peers = append(pw.peers[:i], pw.peers[i+1:]...)
+			return
+		}
+	}
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// Snapshot returns the current peer list and whether the watcher is actively running.
+func (pw *peerWatcher) Snapshot() ([]Peer, bool) {
+	pw.mu.RLock()
+	defer pw.mu.RUnlock()
+	return append([]Peer(nil), pw.peers...), pw.enabled
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
@@ -22,6 +23,7 @@ type CNFStatus struct {
 	Environment string       `json:"environment"`
 	K8sNode     string       `json:"k8s_node"`
 	Security    SecurityInfo `json:"security"`
+	Pod         PodInfo      `json:"pod"`
 }
 
 // SecurityInfo holds security-related information
@@ -45,8 +47,9 @@ type TestResult struct {
 	Duration time.Duration `json:"duration"`
 }
 
-// Global variable to store CNF status
-var cnfStatus CNFStatus
+// statusStore guards CNFStatus so scanHandler's mutations don't race with concurrent
+// readers such as statusHandler.
+var statusStore *Store
 
 // initializes the CNF status with default values
 func init() {
@@ -55,7 +58,7 @@ func init() {
 		nodeName = "unknown-node"
 	}
 
-	cnfStatus = CNFStatus{
+	statusStore = NewStore(CNFStatus{
 		ID:          generateID(),
 		Name:        "Simple-CNFSimulator",
 		Version:     "1.0.0",
@@ -63,13 +66,14 @@ func init() {
 		StartedAt:   time.Now(),
 		Environment: os.Getenv("ENVIRONMENT"),
 		K8sNode:     nodeName,
+		Pod:         podInfoFromEnv(),
 		Security: SecurityInfo{
 			ScanStatus:      "completed",
 			LastScan:        time.Now().Format(time.RFC3339),
 			Vulnerabilities: 0,
 			SecurityRating:  "A",
 		},
-	}
+	})
 }
 
 // generateID creates a simple unique ID for the CNF instance
@@ -84,12 +88,13 @@ func healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("X-Frame-Options", "DENY")
 	w.Header().Set("X-XSS-Protection", "1; mode=block")
 
+	status, _ := statusStore.Get()
 	response := map[string]interface{}{
 		"status":          "healthy",
 		"service":         "cnf-simulator",
 		"timestamp":       time.Now().Format(time.RFC3339),
-		"security_rating": cnfStatus.Security.SecurityRating,
-		"vulnerabilities": cnfStatus.Security.Vulnerabilities,
+		"security_rating": status.Security.SecurityRating,
+		"vulnerabilities": status.Security.Vulnerabilities,
 	}
 	json.NewEncoder(w).Encode(response)
 }
@@ -113,25 +118,36 @@ func readyHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
-// statusHandler returns detailed status information about the CNF
+// statusHandler returns detailed status information about the CNF. It supports an
+// If-None-Match conditional request keyed on the store's resourceVersion, so clients can
+// long-poll for changes without re-fetching an unchanged body. This is a read-only path:
+// resourceVersion only advances on genuine mutations (e.g. /scan), never on a plain GET,
+// otherwise If-None-Match could never match a previously cached ETag.
 func statusHandler(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	status, version := statusStore.Get()
 
-	// Update status with current timestamp
-	cnfStatus.Status = "running"
-	cnfStatus.Security.LastScan = time.Now().Format(time.RFC3339)
+	etag := fmt.Sprintf(`"%d"`, version)
+	w.Header().Set("ETag", etag)
 
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 	response := map[string]interface{}{
-		"id":                cnfStatus.ID,
-		"name":              cnfStatus.Name,
-		"version":           cnfStatus.Version,
-		"status":            cnfStatus.Status,
-		"started_at":        cnfStatus.StartedAt.Format(time.RFC3339),
-		"environment":       cnfStatus.Environment,
-		"k8s_node":          cnfStatus.K8sNode,
+		"id":                status.ID,
+		"name":              status.Name,
+		"version":           status.Version,
+		"status":            status.Status,
+		"started_at":        status.StartedAt.Format(time.RFC3339),
+		"environment":       status.Environment,
+		"k8s_node":          status.K8sNode,
+		"pod":               status.Pod,
 		"current_time":      time.Now().Format(time.RFC3339),
-		"uptime_seconds":    int(time.Since(cnfStatus.StartedAt).Seconds()),
-		"security":          cnfStatus.Security,
+		"uptime_seconds":    int(time.Since(status.StartedAt).Seconds()),
+		"security":          status.Security,
+		"resource_version":  version,
 		"validation_passed": true, // For deployment verification
 		"ready_for_traffic": true, // For deployment verification
 	}
@@ -142,14 +158,22 @@ func statusHandler(w http.ResponseWriter, r *http.Request) {
 func securityHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	status, _ := statusStore.Get()
+
 	// Check for security threshold violations
-	thresholdViolations := checkSecurityThresholds()
+	thresholdViolations := checkSecurityThresholds(status)
+
+	var vulns []Vulnerability
+	if report, ok := latestScan(); ok {
+		vulns = filterBySeverity(report.Vulnerabilities, parseSeverityParam(r))
+	}
 
 	response := map[string]interface{}{
-		"scan_status":          cnfStatus.Security.ScanStatus,
-		"last_scan":            cnfStatus.Security.LastScan,
-		"vulnerabilities":      cnfStatus.Security.Vulnerabilities,
-		"security_rating":      cnfStatus.Security.SecurityRating,
+		"scan_status":          status.Security.ScanStatus,
+		"last_scan":            status.Security.LastScan,
+		"vulnerabilities":      status.Security.Vulnerabilities,
+		"vulnerability_list":   vulns,
+		"security_rating":      status.Security.SecurityRating,
 		"security_policy":      "strict",
 		"compliance":           "SOC2,ISO27001",
 		"threshold_violations": thresholdViolations,
@@ -160,20 +184,29 @@ func securityHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// parseSeverityParam reads the comma-separated ?severity=HIGH,CRITICAL query param.
+func parseSeverityParam(r *http.Request) []string {
+	raw := r.URL.Query().Get("severity")
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, ",")
+}
+
 // checkSecurityThresholds checks if security metrics are within acceptable bounds
-func checkSecurityThresholds() []string {
+func checkSecurityThresholds(status CNFStatus) []string {
 	var violations []string
 
 	minRating := os.Getenv("MINIMUM_SECURITY_RATING")
-	if minRating != "" && cnfStatus.Security.SecurityRating > minRating {
-		violations = append(violations, fmt.Sprintf("Security rating %s is below minimum %s", cnfStatus.Security.SecurityRating, minRating))
+	if minRating != "" && status.Security.SecurityRating > minRating {
+		violations = append(violations, fmt.Sprintf("Security rating %s is below minimum %s", status.Security.SecurityRating, minRating))
 	}
 
 	maxVulns := os.Getenv("MAX_VULNERABILITIES")
 	if maxVulns != "" {
 		if maxVulnsInt, err := strconv.Atoi(maxVulns); err == nil {
-			if cnfStatus.Security.Vulnerabilities > maxVulnsInt {
-				violations = append(violations, fmt.Sprintf("Vulnerabilities count %d exceeds maximum %d", cnfStatus.Security.Vulnerabilities, maxVulnsInt))
+			if status.Security.Vulnerabilities > maxVulnsInt {
+				violations = append(violations, fmt.Sprintf("Vulnerabilities count %d exceeds maximum %d", status.Security.Vulnerabilities, maxVulnsInt))
 			}
 		}
 	}
@@ -208,7 +241,19 @@ func configHandler(w http.ResponseWriter, r *http.Request) {
 		if len(parts) == 2 {
 			// Only expose environment variables that start with APP_ or CNF_
 			if strings.HasPrefix(parts[0], "APP_") || strings.HasPrefix(parts[0], "CNF_") {
-				envVarsJSON[parts[0]] = maskSensitiveData(parts[0], parts[1])
+				envVarsJSON[parts[0]] = maskSensitiveData(parts[0], getAppConfig(parts[0]))
+			}
+		}
+	}
+	// Vault-backed secrets don't always have a mirrored process env var, so they'd
+	// otherwise be invisible here even though getAppConfig would happily resolve them.
+	if secretStore != nil {
+		for _, key := range secretStore.Keys() {
+			if _, alreadyListed := envVarsJSON[key]; alreadyListed {
+				continue
+			}
+			if strings.HasPrefix(key, "APP_") || strings.HasPrefix(key, "CNF_") {
+				envVarsJSON[key] = maskSensitiveData(key, getAppConfig(key))
 			}
 		}
 	}
@@ -238,6 +283,37 @@ func maskSensitiveData(key, value string) string {
 	return value
 }
 
+// peersHandler returns the sibling pods discovered by the in-cluster peer watcher,
+// demonstrating multi-instance topology awareness. Falls back to an empty, disabled
+// response when IN_CLUSTER is not enabled or the watcher couldn't start.
+func peersHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	peerList, enabled := peers.Snapshot()
+	response := map[string]interface{}{
+		"enabled": enabled,
+		"peers":   peerList,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// secretsStatusHandler reports the lease state of every secret tracked by the Vault
+// secret store. Returns an empty list when Vault integration is not configured.
+func secretsStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var statuses []secretStatus
+	if secretStore != nil {
+		statuses = secretStore.Status()
+	}
+
+	response := map[string]interface{}{
+		"vault_enabled": secretStore != nil,
+		"secrets":       statuses,
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
 // scanHandler triggers a security scan and updates security metrics
 func scanHandler(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -247,27 +323,39 @@ func scanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Header().Set("Content-Type", "application/json")
 
-	// Simulate a security scan process
 	scanStartTime := time.Now()
+	scanID := fmt.Sprintf("scan-%d", scanStartTime.Unix())
 
-	// Update security status with simulated scan results
-	cnfStatus.Security.LastScan = scanStartTime.Format(time.RFC3339)
-	cnfStatus.Security.ScanStatus = "completed"
-
-	// Generate random vulnerabilities count for simulation
-	cnfStatus.Security.Vulnerabilities = 3 // Simulated result
-
-	// Calculate security rating based on vulnerabilities
-	if cnfStatus.Security.Vulnerabilities == 0 {
-		cnfStatus.Security.SecurityRating = "A"
-	} else if cnfStatus.Security.Vulnerabilities <= 2 {
-		cnfStatus.Security.SecurityRating = "B"
-	} else if cnfStatus.Security.Vulnerabilities <= 5 {
-		cnfStatus.Security.SecurityRating = "C"
-	} else {
-		cnfStatus.Security.SecurityRating = "D"
+	backend := newScanner()
+	image := imageRef()
+	vulns, err := backend.Scan(image)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("scan failed: %v", err), http.StatusInternalServerError)
+		return
+	}
+	scanCompleteTime := time.Now()
+
+	status, _, err := statusStore.UpdateWithRetry(func(current CNFStatus) (CNFStatus, error) {
+		current.Security.LastScan = scanCompleteTime.Format(time.RFC3339)
+		current.Security.ScanStatus = "completed"
+		current.Security.Vulnerabilities = len(vulns)
+		current.Security.SecurityRating = ratingFromVulnerabilities(vulns)
+		return current, nil
+	})
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to update status: %v", err), http.StatusInternalServerError)
+		return
 	}
 
+	recordScan(ScanReport{
+		ScanID:          scanID,
+		Image:           image,
+		Backend:         backend.Name(),
+		StartedAt:       scanStartTime,
+		CompletedAt:     scanCompleteTime,
+		Vulnerabilities: vulns,
+	})
+
 	// Check if vulnerabilities exceed the threshold
 	maxVulnsStr := os.Getenv("MAX_VULNERABILITIES")
 	maxVulns := 5 // default value
@@ -277,22 +365,40 @@ func scanHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	filtered := filterBySeverity(vulns, parseSeverityParam(r))
+
 	scanResult := map[string]interface{}{
 		"status":                "success",
-		"scan_id":               fmt.Sprintf("scan-%d", time.Now().Unix()),
+		"scan_id":               scanID,
+		"scanner_backend":       backend.Name(),
+		"image":                 image,
 		"scan_started":          scanStartTime.Format(time.RFC3339),
-		"scan_completed":        time.Now().Format(time.RFC3339),
-		"duration_ms":           time.Since(scanStartTime).Milliseconds(),
-		"vulnerabilities_found": cnfStatus.Security.Vulnerabilities,
-		"security_rating":       cnfStatus.Security.SecurityRating,
+		"scan_completed":        scanCompleteTime.Format(time.RFC3339),
+		"duration_ms":           scanCompleteTime.Sub(scanStartTime).Milliseconds(),
+		"vulnerabilities_found": status.Security.Vulnerabilities,
+		"vulnerabilities":       filtered,
+		"security_rating":       status.Security.SecurityRating,
 		"max_allowed_vulns":     maxVulns,
-		"scan_passed":           cnfStatus.Security.Vulnerabilities <= maxVulns,
-		"message":               fmt.Sprintf("Security scan completed with %d vulnerabilities found", cnfStatus.Security.Vulnerabilities),
+		"scan_passed":           status.Security.Vulnerabilities <= maxVulns,
+		"message":               fmt.Sprintf("Security scan completed with %d vulnerabilities found", status.Security.Vulnerabilities),
 	}
 
 	json.NewEncoder(w).Encode(scanResult)
 }
 
+// sbomHandler returns a CycloneDX-formatted software bill of materials derived from the
+// most recent scan's vulnerability list.
+func sbomHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	var vulns []Vulnerability
+	if report, ok := latestScan(); ok {
+		vulns = report.Vulnerabilities
+	}
+
+	json.NewEncoder(w).Encode(buildSBOM(vulns))
+}
+
 // infoHandler provides general information about the CNF
 func infoHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -309,6 +415,10 @@ func infoHandler(w http.ResponseWriter, r *http.Request) {
 			"/security - Security scan information",
 			"/quality - Quality metrics information",
 			"/scan - Trigger security vulnerability scan (POST only)",
+			"/sbom - Software bill of materials in CycloneDX format",
+			"/metrics - Prometheus metrics in text exposition format",
+			"/secrets/status - Vault-backed secret lease status",
+			"/peers - Sibling pod topology (requires IN_CLUSTER=true)",
 		},
 		"version": "1.0.0",
 		"author":  "O-Cloud CNF Simulator",
@@ -330,29 +440,49 @@ func main() {
 		port = "8080"
 	}
 
+	startupStatus, _ := statusStore.Get()
 	fmt.Printf("Starting CNF Simulator on port %s\n", port)
-	fmt.Printf("CNF Instance ID: %s\n", cnfStatus.ID)
-	fmt.Printf("Running on Kubernetes Node: %s\n", cnfStatus.K8sNode)
-	fmt.Printf("Environment: %s\n", cnfStatus.Environment)
-
-	// Define HTTP routes
-	http.HandleFunc("/health", healthHandler)
-	http.HandleFunc("/ready", readyHandler) // Readiness probe endpoint
-	http.HandleFunc("/status", statusHandler)
-	http.HandleFunc("/config", configHandler)
-	http.HandleFunc("/info", infoHandler)
-	http.HandleFunc("/security", securityHandler)
-	http.HandleFunc("/quality", qualityHandler)
-	http.HandleFunc("/scan", scanHandler) // New security scan endpoint
+	fmt.Printf("CNF Instance ID: %s\n", startupStatus.ID)
+	fmt.Printf("Running on Kubernetes Node: %s\n", startupStatus.K8sNode)
+	fmt.Printf("Environment: %s\n", startupStatus.Environment)
+
+	shutdownTracing, err := initTracing()
+	if err != nil {
+		log.Fatalf("failed to initialize tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	if err := initSecretStore(context.Background()); err != nil {
+		log.Printf("vault secret store disabled: %v", err)
+	}
+
+	startPeerWatcher(context.Background())
+
+	// Define HTTP routes, each wrapped with metrics/tracing instrumentation
+	http.HandleFunc("/health", instrument("health", healthHandler))
+	http.HandleFunc("/ready", instrument("ready", readyHandler)) // Readiness probe endpoint
+	http.HandleFunc("/status", instrument("status", statusHandler))
+	http.HandleFunc("/config", instrument("config", configHandler))
+	http.HandleFunc("/info", instrument("info", infoHandler))
+	http.HandleFunc("/security", instrument("security", securityHandler))
+	http.HandleFunc("/quality", instrument("quality", qualityHandler))
+	http.HandleFunc("/scan", instrument("scan", scanHandler)) // New security scan endpoint
+	http.HandleFunc("/sbom", instrument("sbom", sbomHandler)) // CycloneDX SBOM endpoint
+	http.HandleFunc("/secrets/status", instrument("secrets_status", secretsStatusHandler))
+	http.HandleFunc("/peers", instrument("peers", peersHandler))
+	http.Handle("/metrics", instrument("metrics", func(w http.ResponseWriter, r *http.Request) {
+		refreshCNFGauges()
+		metricsHandler.ServeHTTP(w, r)
+	}))
 
 	// Default handler
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+	http.HandleFunc("/", instrument("root", func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path != "/" {
 			http.NotFound(w, r)
 			return
 		}
 		statusHandler(w, r)
-	})
+	}))
 
 	// Start the HTTP server
 	log.Fatal(http.ListenAndServe(":"+port, nil))
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prometheus metrics registered for every instrumented HTTP handler.
+var (
+	httpRequestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "cnf_http_requests_total",
+		Help: "Total number of HTTP requests processed, labeled by handler and status code.",
+	}, []string{"handler", "method", "status"})
+
+	httpRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cnf_http_request_duration_seconds",
+		Help:    "Latency distribution of HTTP requests, labeled by handler.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"handler", "method"})
+
+	httpRequestsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "cnf_http_requests_in_flight",
+		Help: "Number of HTTP requests currently being served, labeled by handler.",
+	}, []string{"handler"})
+
+	httpResponseSize = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "cnf_http_response_size_bytes",
+		Help:    "Size of HTTP responses, labeled by handler.",
+		Buckets: prometheus.ExponentialBuckets(64, 4, 8),
+	}, []string{"handler"})
+
+	cnfVulnerabilitiesGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cnf_vulnerabilities",
+		Help: "Number of vulnerabilities found in the most recent security scan.",
+	})
+
+	cnfSecurityRatingGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cnf_security_rating",
+		Help: "Security rating of the CNF encoded numerically (A=4, B=3, C=2, D=1, F=0).",
+	})
+
+	cnfUptimeSecondsGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "cnf_uptime_seconds",
+		Help: "Seconds since the CNF instance started.",
+	})
+)
+
+// securityRatingToValue encodes a letter security rating as a Prometheus-friendly number.
+func securityRatingToValue(rating string) float64 {
+	switch rating {
+	case "A":
+		return 4
+	case "B":
+		return 3
+	case "C":
+		return 2
+	case "D":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// refreshCNFGauges syncs the CNF-specific gauges with the current in-memory status.
+// Scraped on every /metrics request so the values never go stale between scans.
+func refreshCNFGauges() {
+	status, _ := statusStore.Get()
+	cnfVulnerabilitiesGauge.Set(float64(status.Security.Vulnerabilities))
+	cnfSecurityRatingGauge.Set(securityRatingToValue(status.Security.SecurityRating))
+	cnfUptimeSecondsGauge.Set(time.Since(status.StartedAt).Seconds())
+}
+
+// tracer is the package-wide OpenTelemetry tracer used by the instrumentation middleware.
+var tracer = otel.Tracer("cnf-simulator")
+
+// initTracing configures the global OTel tracer provider with an OTLP exporter pointed
+// at OTEL_EXPORTER_OTLP_ENDPOINT. When the endpoint is unset, tracing stays a no-op.
+func initTracing() (func(context.Context) error, error) {
+	endpoint := os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	exporter, err := otlptracegrpc.New(ctx, otlptracegrpc.WithEndpoint(endpoint), otlptracegrpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+
+	status, _ := statusStore.Get()
+	res, err := resource.New(ctx,
+		resource.WithAttributes(
+			semconv.ServiceName("cnf-simulator"),
+			semconv.ServiceVersion(status.Version),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	return provider.Shutdown, nil
+}
+
+// statusRecorder wraps http.ResponseWriter so the instrumentation middleware can observe
+// the status code and response size written by the wrapped handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+// instrument wraps an http.HandlerFunc with Prometheus metrics recording and an OTel span
+// propagated from the incoming W3C traceparent header.
+func instrument(name string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer))
+		defer span.End()
+
+		httpRequestsInFlight.WithLabelValues(name).Inc()
+		defer httpRequestsInFlight.WithLabelValues(name).Dec()
+
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler(rec, r.WithContext(ctx))
+		duration := time.Since(start)
+
+		status := strconv.Itoa(rec.status)
+		httpRequestsTotal.WithLabelValues(name, r.Method, status).Inc()
+		httpRequestDuration.WithLabelValues(name, r.Method).Observe(duration.Seconds())
+		httpResponseSize.WithLabelValues(name).Observe(float64(rec.size))
+	}
+}
+
+// metricsHandler exposes Prometheus metrics, including Go runtime metrics registered by
+// promauto's default registerer, in text exposition format.
+var metricsHandler = promhttp.Handler()
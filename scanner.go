@@ -0,0 +1,305 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Vulnerability is the normalized representation of a single finding, regardless of
+// which scanner backend produced it.
+type Vulnerability struct {
+	ID           string  `json:"id"`
+	Severity     string  `json:"severity"`
+	Package      string  `json:"package"`
+	FixedVersion string  `json:"fixed_version"`
+	CVSS         float64 `json:"cvss"`
+}
+
+// ScanReport is one completed scan, persisted so /security and /scan can serve
+// historical results without re-invoking the backend.
+type ScanReport struct {
+	ScanID          string          `json:"scan_id"`
+	Image           string          `json:"image"`
+	Backend         string          `json:"backend"`
+	StartedAt       time.Time       `json:"started_at"`
+	CompletedAt     time.Time       `json:"completed_at"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities"`
+}
+
+// Scanner is implemented by each supported vulnerability scanning backend.
+type Scanner interface {
+	// Name identifies the backend, e.g. "trivy", "grype", "static".
+	Name() string
+	// Scan runs the backend against imageRef and returns normalized vulnerabilities.
+	Scan(imageRef string) ([]Vulnerability, error)
+}
+
+// maxScanHistory bounds how many past scans are kept in memory.
+const maxScanHistory = 20
+
+// scanHistory holds the last maxScanHistory completed scans, most recent last.
+var (
+	scanHistoryMu sync.Mutex
+	scanHistory   []ScanReport
+)
+
+// recordScan appends a report to history, evicting the oldest entry once full.
+func recordScan(report ScanReport) {
+	scanHistoryMu.Lock()
+	defer scanHistoryMu.Unlock()
+
+	scanHistory = append(scanHistory, report)
+	if len(scanHistory) > maxScanHistory {
+		scanHistory = scanHistory[len(scanHistory)-maxScanHistory:]
+	}
+}
+
+// latestScan returns the most recent scan report, or false if none has run yet.
+func latestScan() (ScanReport, bool) {
+	scanHistoryMu.Lock()
+	defer scanHistoryMu.Unlock()
+
+	if len(scanHistory) == 0 {
+		return ScanReport{}, false
+	}
+	return scanHistory[len(scanHistory)-1], true
+}
+
+// newScanner selects a Scanner backend based on SCANNER_BACKEND. Defaults to "static"
+// so the simulator keeps working without Trivy/Grype installed.
+func newScanner() Scanner {
+	switch os.Getenv("SCANNER_BACKEND") {
+	case "trivy":
+		return trivyScanner{}
+	case "grype":
+		return grypeScanner{}
+	default:
+		return staticScanner{}
+	}
+}
+
+// imageRef resolves the container image to scan from the pod's downward API injection.
+func imageRef() string {
+	image := os.Getenv("KUBERNETES_POD_IMAGE")
+	if image == "" {
+		image = "unknown"
+	}
+	return image
+}
+
+// runJSONCommand executes name with args and decodes its stdout as JSON into v.
+func runJSONCommand(v interface{}, name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("%s failed: %v (stderr: %s)", name, err, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), v); err != nil {
+		return fmt.Errorf("failed to parse %s JSON output: %v", name, err)
+	}
+	return nil
+}
+
+// trivyScanner invokes the Trivy CLI and normalizes its JSON report.
+type trivyScanner struct{}
+
+func (trivyScanner) Name() string { return "trivy" }
+
+func (trivyScanner) Scan(image string) ([]Vulnerability, error) {
+	var report struct {
+		Results []struct {
+			Vulnerabilities []struct {
+				VulnerabilityID string `json:"VulnerabilityID"`
+				PkgName         string `json:"PkgName"`
+				Severity        string `json:"Severity"`
+				FixedVersion    string `json:"FixedVersion"`
+				CVSS            map[string]struct {
+					V3Score float64 `json:"V3Score"`
+				} `json:"CVSS"`
+			} `json:"Vulnerabilities"`
+		} `json:"Results"`
+	}
+
+	if err := runJSONCommand(&report, "trivy", "image", "--format", "json", "--quiet", image); err != nil {
+		return nil, err
+	}
+
+	var vulns []Vulnerability
+	for _, result := range report.Results {
+		for _, v := range result.Vulnerabilities {
+			var cvss float64
+			for _, source := range v.CVSS {
+				if source.V3Score > cvss {
+					cvss = source.V3Score
+				}
+			}
+			vulns = append(vulns, Vulnerability{
+				ID:           v.VulnerabilityID,
+				Severity:     v.Severity,
+				Package:      v.PkgName,
+				FixedVersion: v.FixedVersion,
+				CVSS:         cvss,
+			})
+		}
+	}
+	return vulns, nil
+}
+
+// grypeScanner invokes the Grype CLI and normalizes its JSON report.
+type grypeScanner struct{}
+
+func (grypeScanner) Name() string { return "grype" }
+
+func (grypeScanner) Scan(image string) ([]Vulnerability, error) {
+	var report struct {
+		Matches []struct {
+			Vulnerability struct {
+				ID       string `json:"id"`
+				Severity string `json:"severity"`
+				Fix      struct {
+					Versions []string `json:"versions"`
+				} `json:"fix"`
+				CVSS []struct {
+					Metrics struct {
+						BaseScore float64 `json:"baseScore"`
+					} `json:"metrics"`
+				} `json:"cvss"`
+			} `json:"vulnerability"`
+			Artifact struct {
+				Name string `json:"name"`
+			} `json:"artifact"`
+		} `json:"matches"`
+	}
+
+	if err := runJSONCommand(&report, "grype", image, "-o", "json"); err != nil {
+		return nil, err
+	}
+
+	var vulns []Vulnerability
+	for _, m := range report.Matches {
+		var fixedVersion string
+		if len(m.Vulnerability.Fix.Versions) > 0 {
+			fixedVersion = m.Vulnerability.Fix.Versions[0]
+		}
+		var cvss float64
+		if len(m.Vulnerability.CVSS) > 0 {
+			cvss = m.Vulnerability.CVSS[0].Metrics.BaseScore
+		}
+		vulns = append(vulns, Vulnerability{
+			ID:           m.Vulnerability.ID,
+			Severity:     m.Vulnerability.Severity,
+			Package:      m.Artifact.Name,
+			FixedVersion: fixedVersion,
+			CVSS:         cvss,
+		})
+	}
+	return vulns, nil
+}
+
+// staticScanner is a deterministic backend for local development and tests, used when
+// SCANNER_BACKEND is unset or names no known backend. A selected trivy or grype backend
+// whose binary is missing is not replaced by it; its Scan returns an error instead.
+type staticScanner struct{}
+
+func (staticScanner) Name() string { return "static" }
+
+func (staticScanner) Scan(image string) ([]Vulnerability, error) {
+	return []Vulnerability{
+		{ID: "CVE-2023-0001", Severity: "HIGH", Package: "openssl", FixedVersion: "3.0.9", CVSS: 7.5},
+		{ID: "CVE-2023-0002", Severity: "MEDIUM", Package: "curl", FixedVersion: "8.1.0", CVSS: 5.3},
+		{ID: "CVE-2023-0003", Severity: "LOW", Package: "zlib", FixedVersion: "1.2.13", CVSS: 3.1},
+	}, nil
+}
+
+// ratingFromVulnerabilities derives a letter security rating from the sum of the findings'
+// CVSS scores: "A" with no findings, "B" up to 10, "C" up to 30, "D" above that.
+func ratingFromVulnerabilities(vulns []Vulnerability) string {
+	var weighted float64
+	for _, v := range vulns {
+		weighted += v.CVSS
+	}
+
+	switch {
+	case len(vulns) == 0:
+		return "A"
+	case weighted <= 10:
+		return "B"
+	case weighted <= 30:
+		return "C"
+	default:
+		return "D"
+	}
+}
+
+// filterBySeverity returns the subset of vulns whose Severity is in severities
+// (case-sensitive match against the backend's own severity strings, e.g. "HIGH").
+func filterBySeverity(vulns []Vulnerability, severities []string) []Vulnerability {
+	if len(severities) == 0 {
+		return vulns
+	}
+	allowed := make(map[string]bool, len(severities))
+	for _, s := range severities {
+		allowed[s] = true
+	}
+
+	var filtered []Vulnerability
+	for _, v := range vulns {
+		if allowed[v.Severity] {
+			filtered = append(filtered, v)
+		}
+	}
+	return filtered
+}
+
+// cyclonedxComponent is a minimal CycloneDX component entry for the SBOM endpoint.
+type cyclonedxComponent struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl"`
+}
+
+// buildSBOM assembles a minimal CycloneDX document from the packages referenced by the
+// latest scan's vulnerabilities. A real SBOM would come from a syft/trivy SBOM mode;
+// this derives a best-effort one from what the scanner already told us about.
+func buildSBOM(vulns []Vulnerability) map[string]interface{} {
+	status, _ := statusStore.Get()
+
+	seen := make(map[string]bool)
+	var components []cyclonedxComponent
+	for _, v := range vulns {
+		if seen[v.Package] {
+			continue
+		}
+		seen[v.Package] = true
+		components = append(components, cyclonedxComponent{
+			Type: "library",
+			Name: v.Package,
+			PURL: fmt.Sprintf("pkg:generic/%s", v.Package),
+		})
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].Name < components[j].Name })
+
+	return map[string]interface{}{
+		"bomFormat":   "CycloneDX",
+		"specVersion": "1.5",
+		"version":     1,
+		"metadata": map[string]interface{}{
+			"timestamp": time.Now().Format(time.RFC3339),
+			"component": map[string]string{
+				"type": "application",
+				"name": status.Name,
+			},
+		},
+		"components": components,
+	}
+}
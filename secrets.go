@@ -0,0 +1,297 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// secretEntry is a single cached secret along with the lease bookkeeping needed to
+// renew it before it expires. leaseDuration and renewedAt are written by renewLoop and
+// read by Status from a different goroutine, so they're guarded by mu rather than left
+// as plain fields.
+type secretEntry struct {
+	value   atomic.Value // string
+	leaseID string
+
+	mu            sync.Mutex
+	leaseDuration time.Duration
+	renewedAt     time.Time
+
+	renewalErrors int64
+	stop          chan struct{}
+}
+
+// setLease atomically updates the lease bookkeeping after a successful renewal.
+func (e *secretEntry) setLease(duration time.Duration, renewedAt time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.leaseDuration = duration
+	e.renewedAt = renewedAt
+}
+
+// lease returns the current lease bookkeeping.
+func (e *secretEntry) lease() (time.Duration, time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leaseDuration, e.renewedAt
+}
+
+// SecretStore pulls APP_*/CNF_* configuration from Vault and keeps each value fresh with
+// a background renewer goroutine, modeled on vaultapi.LifetimeWatcher: sleep until 2/3 of
+// the lease TTL, then renew with backoff, only giving up on a hard revocation.
+type SecretStore struct {
+	client *vaultapi.Client
+	mount  string // KV v2 mount point, e.g. "secret"
+	path   string // KV v2 secret path, e.g. "cnf-simulator/config"
+
+	entries sync.Map // map[string]*secretEntry
+}
+
+// newSecretStore builds a SecretStore from a Vault client authenticated via AppRole.
+// The caller is responsible for calling Configure to start watching keys.
+func newSecretStore(client *vaultapi.Client, mount, path string) *SecretStore {
+	return &SecretStore{client: client, mount: mount, path: path}
+}
+
+// vaultClientFromEnv builds a Vault client and authenticates it via the AppRole backend
+// using VAULT_ADDR, VAULT_ROLE_ID, and VAULT_SECRET_ID. Returns nil if Vault integration
+// is not configured, so callers can fall back to os.Getenv.
+func vaultClientFromEnv() (*vaultapi.Client, error) {
+	addr := os.Getenv("VAULT_ADDR")
+	roleID := os.Getenv("VAULT_ROLE_ID")
+	secretID := os.Getenv("VAULT_SECRET_ID")
+	if addr == "" || roleID == "" || secretID == "" {
+		return nil, nil
+	}
+
+	config := vaultapi.DefaultConfig()
+	config.Address = addr
+	client, err := vaultapi.NewClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create vault client: %w", err)
+	}
+
+	secret, err := client.Logical().Write("auth/approle/login", map[string]interface{}{
+		"role_id":   roleID,
+		"secret_id": secretID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("approle login failed: %w", err)
+	}
+	if secret == nil || secret.Auth == nil {
+		return nil, fmt.Errorf("approle login returned no auth info")
+	}
+	client.SetToken(secret.Auth.ClientToken)
+
+	return client, nil
+}
+
+// Configure fetches key from the configured KV v2 path, caches its value, and starts a
+// renewer goroutine that keeps it fresh for the life of the process.
+func (s *SecretStore) Configure(ctx context.Context, key string) error {
+	secret, err := s.client.KVv2(s.mount).Get(ctx, s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read secret %s: %w", s.path, err)
+	}
+
+	raw, ok := secret.Data[key].(string)
+	if !ok {
+		return fmt.Errorf("secret %s has no string field %q", s.path, key)
+	}
+
+	entry := &secretEntry{
+		leaseID:       secret.Raw.LeaseID,
+		leaseDuration: time.Duration(secret.Raw.LeaseDuration) * time.Second,
+		renewedAt:     time.Now(),
+		stop:          make(chan struct{}),
+	}
+	entry.value.Store(raw)
+	s.entries.Store(key, entry)
+
+	if entry.leaseDuration > 0 {
+		go s.renewLoop(ctx, key, entry)
+	}
+
+	return nil
+}
+
+// renewLoop mirrors vaultapi.LifetimeWatcher's RenewBehaviorIgnoreErrors: it sleeps until
+// 2/3 of the lease TTL has elapsed, then attempts to renew. Transient failures are retried
+// with exponential backoff; only a hard revocation (lease gone) stops the loop.
+func (s *SecretStore) renewLoop(ctx context.Context, key string, entry *secretEntry) {
+	backoff := time.Second
+	retrying := false
+
+	for {
+		leaseDuration, _ := entry.lease()
+		sleepFor := leaseDuration * 2 / 3
+		if retrying {
+			// The backoff wait has already elapsed; renew again right away.
+			sleepFor = 0
+		}
+		select {
+		case <-time.After(sleepFor):
+		case <-entry.stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+
+		secret, err := s.client.Sys().RenewWithContext(ctx, entry.leaseID, 0)
+		if err != nil {
+			atomic.AddInt64(&entry.renewalErrors, 1)
+			if isHardRevocation(err) {
+				log.Printf("secret %s lease %s was revoked, stopping renewal: %v", key, entry.leaseID, err)
+				return
+			}
+			log.Printf("secret %s renewal failed, retrying in %s: %v", key, backoff, err)
+			select {
+			case <-time.After(backoff):
+			case <-entry.stop:
+				return
+			case <-ctx.Done():
+				return
+			}
+			backoff = nextBackoff(backoff)
+			retrying = true
+			continue
+		}
+
+		retrying = false
+		backoff = time.Second
+		entry.setLease(time.Duration(secret.LeaseDuration)*time.Second, time.Now())
+	}
+}
+
+// nextBackoff doubles the backoff duration up to a one minute ceiling, with a little
+// jitter so many renewers don't retry in lockstep.
+func nextBackoff(cur time.Duration) time.Duration {
+	next := cur * 2
+	if next > time.Minute {
+		next = time.Minute
+	}
+	jitter := time.Duration(rand.Int63n(int64(next) / 4))
+	return next + jitter
+}
+
+// isHardRevocation reports whether a Vault renewal error indicates the lease is gone for
+// good (e.g. 404/bad request) rather than a transient network/server error.
+func isHardRevocation(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "lease not found") || strings.Contains(msg, "bad request")
+}
+
+// Get returns the current cached value for key and whether it is present.
+func (s *SecretStore) Get(key string) (string, bool) {
+	v, ok := s.entries.Load(key)
+	if !ok {
+		return "", false
+	}
+	entry := v.(*secretEntry)
+	val, _ := entry.value.Load().(string)
+	return val, true
+}
+
+// Keys returns every key currently tracked by the store, including ones that were never
+// mirrored into the process environment.
+func (s *SecretStore) Keys() []string {
+	var keys []string
+	s.entries.Range(func(k, _ interface{}) bool {
+		keys = append(keys, k.(string))
+		return true
+	})
+	return keys
+}
+
+// secretStatus describes one secret's lease state, for /secrets/status.
+type secretStatus struct {
+	Key           string  `json:"key"`
+	LeaseID       string  `json:"lease_id"`
+	RemainingTTL  float64 `json:"remaining_ttl_seconds"`
+	LastRenewedAt string  `json:"last_renewed_at"`
+	RenewalErrors int64   `json:"renewal_errors"`
+}
+
+// Status returns the lease state of every secret currently tracked by the store.
+func (s *SecretStore) Status() []secretStatus {
+	var statuses []secretStatus
+	s.entries.Range(func(k, v interface{}) bool {
+		key := k.(string)
+		entry := v.(*secretEntry)
+		leaseDuration, renewedAt := entry.lease()
+		remaining := leaseDuration - time.Since(renewedAt)
+		if remaining < 0 {
+			remaining = 0
+		}
+		statuses = append(statuses, secretStatus{
+			Key:           key,
+			LeaseID:       entry.leaseID,
+			RemainingTTL:  remaining.Seconds(),
+			LastRenewedAt: renewedAt.Format(time.RFC3339),
+			RenewalErrors: atomic.LoadInt64(&entry.renewalErrors),
+		})
+		return true
+	})
+	return statuses
+}
+
+// secretStore is the process-wide secret store, populated in main() when Vault is
+// configured. It stays nil when VAULT_ADDR is unset, and lookups fall back to os.Getenv.
+var secretStore *SecretStore
+
+// initSecretStore authenticates to Vault via AppRole (when VAULT_ADDR/VAULT_ROLE_ID/
+// VAULT_SECRET_ID are set) and starts watching every APP_*/CNF_* key present in the KV v2
+// secret at VAULT_SECRET_PATH (default "cnf-simulator/config", mount "secret"). A nil
+// return with no error means Vault integration is simply not configured.
+func initSecretStore(ctx context.Context) error {
+	client, err := vaultClientFromEnv()
+	if err != nil {
+		return err
+	}
+	if client == nil {
+		return nil
+	}
+
+	path := os.Getenv("VAULT_SECRET_PATH")
+	if path == "" {
+		path = "cnf-simulator/config"
+	}
+
+	store := newSecretStore(client, "secret", path)
+	secret, err := client.KVv2("secret").Get(ctx, path)
+	if err != nil {
+		return fmt.Errorf("failed to read initial secret data: %w", err)
+	}
+
+	for key := range secret.Data {
+		if !strings.HasPrefix(key, "APP_") && !strings.HasPrefix(key, "CNF_") {
+			continue
+		}
+		if err := store.Configure(ctx, key); err != nil {
+			log.Printf("failed to configure secret watcher for %s: %v", key, err)
+		}
+	}
+
+	secretStore = store
+	return nil
+}
+
+// getAppConfig resolves an APP_*/CNF_* configuration value, preferring the live Vault
+// secret store over the process environment when one has been configured for that key.
+func getAppConfig(key string) string {
+	if secretStore != nil {
+		if val, ok := secretStore.Get(key); ok {
+			return val
+		}
+	}
+	return os.Getenv(key)
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"sync"
+)
+
+// Store guards CNFStatus behind a RWMutex and tracks a resourceVersion incremented on
+// every mutation, so concurrent readers/writers (statusHandler and scanHandler both hit
+// this under load) don't race on the same struct.
+type Store struct {
+	mu              sync.RWMutex
+	status          CNFStatus
+	resourceVersion uint64
+}
+
+// NewStore seeds a Store with the given initial status at resourceVersion 1.
+func NewStore(initial CNFStatus) *Store {
+	return &Store{status: initial, resourceVersion: 1}
+}
+
+// Get returns a copy of the current status along with its resourceVersion.
+func (s *Store) Get() (CNFStatus, uint64) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.status, s.resourceVersion
+}
+
+// errCASConflict is returned internally by casUpdate when the resourceVersion changed
+// out from under the caller between read and write.
+var errCASConflict = errors.New("resourceVersion conflict")
+
+// casUpdate applies newStatus only if the store's resourceVersion still equals expected,
+// incrementing it on success.
+func (s *Store) casUpdate(expected uint64, newStatus CNFStatus) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.resourceVersion != expected {
+		return errCASConflict
+	}
+	s.status = newStatus
+	s.resourceVersion++
+	return nil
+}
+
+// UpdateWithRetry reads the current status, applies fn, and attempts a compare-and-swap
+// write. This mirrors etcd3's store mustCheckData/origStateIsCurrent retry loop: the
+// happy path reads once and CASes once; state is only re-read and the transform re-applied
+// when the CAS actually lost a race to a concurrent writer.
+func (s *Store) UpdateWithRetry(fn func(current CNFStatus) (CNFStatus, error)) (CNFStatus, uint64, error) {
+	for {
+		current, version := s.Get()
+
+		updated, err := fn(current)
+		if err != nil {
+			return CNFStatus{}, 0, err
+		}
+
+		if err := s.casUpdate(version, updated); err != nil {
+			if errors.Is(err, errCASConflict) {
+				continue
+			}
+			return CNFStatus{}, 0, err
+		}
+
+		return updated, version + 1, nil
+	}
+}
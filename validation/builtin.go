@@ -0,0 +1,88 @@
+package main
+
+// securityHeaderExpectations asserts requiredSecurityHeaders are present, without
+// pinning their exact values.
+func securityHeaderExpectations() map[string]string {
+	expect := make(map[string]string, len(requiredSecurityHeaders))
+	for _, h := range requiredSecurityHeaders {
+		expect[h] = ""
+	}
+	return expect
+}
+
+// builtinSuite covers the health, ready, status, config, info, security, quality, scan
+// and metrics handlers registered in main.go's router, so `go run . --suite builtin`
+// exercises them without needing a YAML file on disk. /sbom, /secrets/status and /peers
+// have no case here.
+var builtinSuite = Suite{
+	Name: "builtin",
+	Cases: []TestCase{
+		{
+			Name:           "health returns healthy status",
+			Method:         "GET",
+			Path:           "/health",
+			ExpectStatus:   200,
+			ExpectJSONPath: map[string]string{"$.status": "healthy"},
+			ExpectHeaders:  securityHeaderExpectations(),
+		},
+		{
+			Name:           "ready reports readiness",
+			Method:         "GET",
+			Path:           "/ready",
+			ExpectStatus:   200,
+			ExpectJSONPath: map[string]string{"$.ready": "true"},
+			ExpectHeaders:  securityHeaderExpectations(),
+		},
+		{
+			Name:           "status reports validation passed",
+			Method:         "GET",
+			Path:           "/status",
+			ExpectStatus:   200,
+			ExpectJSONPath: map[string]string{"$.validation_passed": "true"},
+		},
+		{
+			Name:         "config returns config block",
+			Method:       "GET",
+			Path:         "/config",
+			ExpectStatus: 200,
+		},
+		{
+			Name:           "info describes the service",
+			Method:         "GET",
+			Path:           "/info",
+			ExpectStatus:   200,
+			ExpectJSONPath: map[string]string{"$.service": "Cloud-Native Network Function Simulator"},
+		},
+		{
+			Name:         "security reports scan status",
+			Method:       "GET",
+			Path:         "/security",
+			ExpectStatus: 200,
+		},
+		{
+			Name:         "quality reports code coverage",
+			Method:       "GET",
+			Path:         "/quality",
+			ExpectStatus: 200,
+		},
+		{
+			Name:           "scan triggers a security scan",
+			Method:         "POST",
+			Path:           "/scan",
+			ExpectStatus:   200,
+			ExpectJSONPath: map[string]string{"$.status": "success"},
+		},
+		{
+			Name:              "metrics returns valid Prometheus text format",
+			Method:            "GET",
+			Path:              "/metrics",
+			ExpectStatus:      200,
+			ExpectContentType: "text/plain",
+			ExpectBodyContains: []string{
+				"# HELP",
+				"# TYPE",
+				"cnf_vulnerabilities",
+			},
+		},
+	},
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// evalJSONPath resolves a small subset of JSONPath sufficient for response assertions:
+// dot-separated field access and integer array indices, e.g. "$.security.rating" or
+// "$.endpoints[0]". Returns the matched value as a string for comparison.
+func evalJSONPath(doc interface{}, path string) (string, error) {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.TrimPrefix(path, ".")
+	if path == "" {
+		return fmt.Sprintf("%v", doc), nil
+	}
+
+	current := doc
+	for _, token := range splitPathTokens(path) {
+		field, index, hasIndex := parseToken(token)
+
+		if field != "" {
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return "", fmt.Errorf("cannot index field %q into non-object value", field)
+			}
+			v, ok := m[field]
+			if !ok {
+				return "", fmt.Errorf("field %q not found", field)
+			}
+			current = v
+		}
+
+		if hasIndex {
+			arr, ok := current.([]interface{})
+			if !ok {
+				return "", fmt.Errorf("cannot index [%d] into non-array value", index)
+			}
+			if index < 0 || index >= len(arr) {
+				return "", fmt.Errorf("index %d out of range (len %d)", index, len(arr))
+			}
+			current = arr[index]
+		}
+	}
+
+	return fmt.Sprintf("%v", current), nil
+}
+
+// splitPathTokens splits a dot-separated JSONPath remainder into its per-segment tokens.
+func splitPathTokens(path string) []string {
+	var tokens []string
+	for _, t := range strings.Split(path, ".") {
+		if t != "" {
+			tokens = append(tokens, t)
+		}
+	}
+	return tokens
+}
+
+// parseToken splits a token like "items[2]" into its field name and optional array index.
+func parseToken(token string) (field string, index int, hasIndex bool) {
+	open := strings.Index(token, "[")
+	if open == -1 {
+		return token, 0, false
+	}
+	close := strings.Index(token, "]")
+	if close == -1 || close < open {
+		return token, 0, false
+	}
+
+	field = token[:open]
+	idx, err := strconv.Atoi(token[open+1 : close])
+	if err != nil {
+		return field, 0, false
+	}
+	return field, idx, true
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"time"
+)
+
+// junitTestSuite mirrors the subset of the JUnit XML schema that CI systems consume.
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     float64         `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    float64       `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+}
+
+// writeJUnitReport renders results as a JUnit XML report suitable for CI ingestion.
+func writeJUnitReport(path, suiteName string, results []CaseResult) error {
+	suite := junitTestSuite{Name: suiteName, Tests: len(results)}
+
+	var total time.Duration
+	for _, r := range results {
+		total += r.Duration
+		tc := junitTestCase{Name: r.Name, Time: r.Duration.Seconds()}
+		if !r.Passed {
+			suite.Failures++
+			tc.Failure = &junitFailure{Message: r.Message}
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Time = total.Seconds()
+
+	data, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal JUnit report: %w", err)
+	}
+
+	full := append([]byte(xml.Header), data...)
+	return os.WriteFile(path, full, 0o644)
+}
+
+// printTAPReport writes results to stdout in Test Anything Protocol format, for
+// consumption by BATS and other TAP-aware harnesses.
+func printTAPReport(results []CaseResult) {
+	fmt.Printf("1..%d\n", len(results))
+	for i, r := range results {
+		if r.Passed {
+			fmt.Printf("ok %d - %s\n", i+1, r.Name)
+		} else {
+			fmt.Printf("not ok %d - %s\n", i+1, r.Name)
+			fmt.Printf("# %s\n", r.Message)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CaseResult is the outcome of running a single TestCase.
+type CaseResult struct {
+	Name     string
+	Passed   bool
+	Message  string
+	Duration time.Duration
+}
+
+// RunnerOptions controls how a Suite is executed.
+type RunnerOptions struct {
+	BaseURL  string
+	Parallel int
+	FailFast bool
+}
+
+// runSuite executes every case in the suite and returns one CaseResult per case, in the
+// original case order, regardless of how parallel execution interleaved them.
+func runSuite(suite *Suite, opts RunnerOptions) []CaseResult {
+	results := make([]CaseResult, len(suite.Cases))
+
+	parallelism := opts.Parallel
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	var abort bool
+	var abortMu sync.Mutex
+
+	worker := func() {
+		defer wg.Done()
+		for i := range jobs {
+			abortMu.Lock()
+			shouldAbort := abort
+			abortMu.Unlock()
+			if shouldAbort {
+				results[i] = CaseResult{Name: suite.Cases[i].Name, Passed: false, Message: "skipped due to --fail-fast"}
+				continue
+			}
+
+			result := runCaseWithRetries(opts.BaseURL, suite.Cases[i])
+			results[i] = result
+
+			if !result.Passed && opts.FailFast {
+				abortMu.Lock()
+				abort = true
+				abortMu.Unlock()
+			}
+		}
+	}
+
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go worker()
+	}
+	for i := range suite.Cases {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
+
+// runCaseWithRetries runs tc up to tc.Retries+1 times, returning on the first pass or
+// after the final attempt's result once retries are exhausted.
+func runCaseWithRetries(baseURL string, tc TestCase) CaseResult {
+	attempts := tc.Retries + 1
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var result CaseResult
+	for attempt := 0; attempt < attempts; attempt++ {
+		result = runCase(baseURL, tc)
+		if result.Passed {
+			return result
+		}
+	}
+	return result
+}
+
+// runCase issues tc's request and evaluates its assertions.
+func runCase(baseURL string, tc TestCase) CaseResult {
+	start := time.Now()
+
+	timeout := time.Duration(tc.TimeoutMs) * time.Millisecond
+	if tc.TimeoutMs == 0 {
+		timeout = defaultTimeoutMs * time.Millisecond
+	}
+	client := &http.Client{Timeout: timeout}
+
+	method := tc.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	var bodyReader *bytes.Reader
+	if tc.Body != "" {
+		bodyReader = bytes.NewReader([]byte(tc.Body))
+	} else {
+		bodyReader = bytes.NewReader(nil)
+	}
+
+	req, err := http.NewRequest(method, baseURL+tc.Path, bodyReader)
+	if err != nil {
+		return fail(tc.Name, start, fmt.Sprintf("failed to build request: %v", err))
+	}
+	if tc.Body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fail(tc.Name, start, fmt.Sprintf("request failed: %v", err))
+	}
+	defer resp.Body.Close()
+
+	if tc.ExpectStatus != 0 && resp.StatusCode != tc.ExpectStatus {
+		return fail(tc.Name, start, fmt.Sprintf("expected status %d, got %d", tc.ExpectStatus, resp.StatusCode))
+	}
+
+	for header, want := range tc.ExpectHeaders {
+		got := resp.Header.Get(header)
+		if got == "" {
+			return fail(tc.Name, start, fmt.Sprintf("missing required header %s", header))
+		}
+		if want != "" && got != want {
+			return fail(tc.Name, start, fmt.Sprintf("header %s: expected %q, got %q", header, want, got))
+		}
+	}
+
+	if tc.ExpectContentType != "" {
+		got := resp.Header.Get("Content-Type")
+		if !strings.HasPrefix(got, tc.ExpectContentType) {
+			return fail(tc.Name, start, fmt.Sprintf("expected content type %q, got %q", tc.ExpectContentType, got))
+		}
+	}
+
+	if len(tc.ExpectBodyContains) > 0 || len(tc.ExpectJSONPath) > 0 {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return fail(tc.Name, start, fmt.Sprintf("failed to read response body: %v", err))
+		}
+
+		for _, want := range tc.ExpectBodyContains {
+			if !strings.Contains(string(body), want) {
+				return fail(tc.Name, start, fmt.Sprintf("body missing expected substring %q", want))
+			}
+		}
+
+		if len(tc.ExpectJSONPath) > 0 {
+			var doc interface{}
+			if err := json.Unmarshal(body, &doc); err != nil {
+				return fail(tc.Name, start, fmt.Sprintf("failed to decode JSON response: %v", err))
+			}
+			for path, want := range tc.ExpectJSONPath {
+				got, err := evalJSONPath(doc, path)
+				if err != nil {
+					return fail(tc.Name, start, fmt.Sprintf("json_path %s: %v", path, err))
+				}
+				if got != want {
+					return fail(tc.Name, start, fmt.Sprintf("json_path %s: expected %q, got %q", path, want, got))
+				}
+			}
+		}
+	}
+
+	return CaseResult{Name: tc.Name, Passed: true, Duration: time.Since(start)}
+}
+
+func fail(name string, start time.Time, message string) CaseResult {
+	return CaseResult{Name: name, Passed: false, Message: message, Duration: time.Since(start)}
+}
+
+// summarize renders a short pass/fail count line, used for the default console output.
+func summarize(results []CaseResult) string {
+	var passed, failed int
+	for _, r := range results {
+		if r.Passed {
+			passed++
+		} else {
+			failed++
+		}
+	}
+	return fmt.Sprintf("%d passed, %d failed, %d total", passed, failed, len(results))
+}
+
+// printResults writes one line per case to stdout in the existing ✓/✗ style.
+func printResults(results []CaseResult) {
+	for _, r := range results {
+		if r.Passed {
+			fmt.Printf("✓ %s (%s)\n", r.Name, r.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Printf("✗ %s: %s\n", r.Name, strings.TrimSpace(r.Message))
+		}
+	}
+	fmt.Println(summarize(results))
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TestCase is one conformance check: a request to issue and the assertions to run
+// against its response.
+type TestCase struct {
+	Name               string            `yaml:"name"`
+	Method             string            `yaml:"method"`
+	Path               string            `yaml:"path"`
+	Body               string            `yaml:"body"`
+	ExpectStatus       int               `yaml:"expect_status"`
+	ExpectJSONPath     map[string]string `yaml:"expect_json_path"`
+	ExpectHeaders      map[string]string `yaml:"expect_headers"`
+	ExpectContentType  string            `yaml:"expect_content_type"`
+	ExpectBodyContains []string          `yaml:"expect_body_contains"`
+	TimeoutMs          int               `yaml:"timeout_ms"`
+	Retries            int               `yaml:"retries"`
+}
+
+// Suite is a named collection of test cases, as loaded from a YAML file or a built-in
+// definition.
+type Suite struct {
+	Name  string     `yaml:"name"`
+	Cases []TestCase `yaml:"cases"`
+}
+
+// requiredSecurityHeaders lists the header names a case can reference in its
+// expect_headers block to assert the standard security headers are present, without
+// having to spell out the expected value.
+var requiredSecurityHeaders = []string{"X-Content-Type-Options", "X-Frame-Options", "X-XSS-Protection"}
+
+// loadSuite reads and parses a YAML suite file.
+func loadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite file %s: %w", path, err)
+	}
+
+	var suite Suite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse suite file %s: %w", path, err)
+	}
+	return &suite, nil
+}
+
+// defaultTimeoutMs is used for a case that doesn't set timeout_ms.
+const defaultTimeoutMs = 5000
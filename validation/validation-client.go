@@ -1,95 +1,64 @@
-// validation-client.go - Go client for comprehensive API validation
+// validation-client.go - table-driven conformance test runner for the CNF simulator API.
+//
+// Usage:
+//
+//	go run . --suite path/to/tests.yaml
+//	go run . --suite builtin
+//	go run . --suite builtin --parallel 4 --junit report.xml --tap --fail-fast
 package main
 
 import (
-	"encoding/json"
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"net/http"
-	"time"
+	"os"
 )
 
-type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Version   string    `json:"version"`
-}
-
-type StatusResponse struct {
-	AppName       string            `json:"app_name"`
-	InstanceID    string            `json:"instance_id"`
-	StartTime     time.Time         `json:"start_time"`
-	UptimeSeconds int               `json:"uptime_seconds"`
-	Config        map[string]string `json:"config"`
-}
-
-func validateHealthEndpoint(baseURL string) error {
-	resp, err := http.Get(fmt.Sprintf("%s/health", baseURL))
-	if err != nil {
-		return fmt.Errorf("failed to connect to health endpoint: %v", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("health endpoint returned status code %d", resp.StatusCode)
-	}
-
-	var health HealthResponse
-	if err := json.NewDecoder(resp.Body).Decode(&health); err != nil {
-		return fmt.Errorf("failed to parse health response: %v", err)
-	}
-
-	if health.Status != "healthy" {
-		return fmt.Errorf("health status is not 'healthy': got '%s'", health.Status)
-	}
-
-	fmt.Printf("✓ Health endpoint validation passed: %s\n", health.Status)
-	return nil
-}
-
-func validateStatusEndpoint(baseURL string) error {
-	resp, err := http.Get(fmt.Sprintf("%s/status", baseURL))
+func main() {
+	baseURL := flag.String("base-url", "http://localhost:8080", "base URL of the running CNF simulator")
+	suitePath := flag.String("suite", "builtin", `path to a YAML suite file, or "builtin" for the built-in coverage suite`)
+	parallel := flag.Int("parallel", 4, "number of test cases to run concurrently")
+	failFast := flag.Bool("fail-fast", false, "stop scheduling new cases after the first failure")
+	junitPath := flag.String("junit", "", "write a JUnit XML report to this path")
+	tap := flag.Bool("tap", false, "print results in TAP format instead of the default console output")
+	flag.Parse()
+
+	suite, err := resolveSuite(*suitePath)
 	if err != nil {
-		return fmt.Errorf("failed to connect to status endpoint: %v", err)
+		fmt.Printf("✗ failed to load suite: %v\n", err)
+		os.Exit(1)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("status endpoint returned status code %d", resp.StatusCode)
-	}
+	results := runSuite(suite, RunnerOptions{
+		BaseURL:  *baseURL,
+		Parallel: *parallel,
+		FailFast: *failFast,
+	})
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("failed to read status response: %v", err)
+	if *tap {
+		printTAPReport(results)
+	} else {
+		printResults(results)
 	}
 
-	var status StatusResponse
-	if err := json.Unmarshal(body, &status); err != nil {
-		return fmt.Errorf("failed to parse status response: %v", err)
+	if *junitPath != "" {
+		if err := writeJUnitReport(*junitPath, suite.Name, results); err != nil {
+			fmt.Printf("✗ failed to write JUnit report: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	if status.AppName == "" {
-		return fmt.Errorf("status response missing app_name field")
+	for _, r := range results {
+		if !r.Passed {
+			os.Exit(1)
+		}
 	}
-
-	fmt.Printf("✓ Status endpoint validation passed: %s\n", status.AppName)
-	return nil
 }
 
-func main() {
-	baseURL := "http://localhost:8080" // Should be replaced with actual service URL
-
-	fmt.Println("Starting API endpoint validation...")
-
-	if err := validateHealthEndpoint(baseURL); err != nil {
-		fmt.Printf("✗ Health validation failed: %v\n", err)
-		return
+// resolveSuite loads the named suite: "builtin" for the in-binary coverage suite,
+// anything else is treated as a path to a YAML suite file.
+func resolveSuite(name string) (*Suite, error) {
+	if name == "builtin" {
+		return &builtinSuite, nil
 	}
-
-	if err := validateStatusEndpoint(baseURL); err != nil {
-		fmt.Printf("✗ Status validation failed: %v\n", err)
-		return
-	}
-
-	fmt.Println("All API endpoint validations passed!")
+	return loadSuite(name)
 }